@@ -0,0 +1,35 @@
+package newrelic
+
+import "net/http"
+
+// Transaction represents a single request or background task.  It is
+// returned by Application.StartTransaction and, for web transactions,
+// also implements http.ResponseWriter so it can be used in place of the
+// original writer (see WrapHandleFunc).
+type Transaction interface {
+	http.ResponseWriter
+
+	// SetName sets the name of the Transaction.  Returns
+	// AlreadyEndedErr if called after End.
+	SetName(name string) error
+
+	// NoticeError records an error against the Transaction.
+	NoticeError(err error) error
+
+	// AddAttribute adds a custom key/value pair to the Transaction.
+	AddAttribute(key string, value interface{}) error
+
+	// End finishes the Transaction.  A panic between starting and
+	// ending the Transaction is recorded as an error and re-panicked.
+	End() error
+}
+
+// Application represents this instrumented application.
+type Application interface {
+	// StartTransaction begins a Transaction with the given name.  w
+	// and r may be nil for a background transaction.
+	StartTransaction(name string, w http.ResponseWriter, r *http.Request) Transaction
+
+	// RecordCustomEvent records a custom event of the given type.
+	RecordCustomEvent(eventType string, params map[string]interface{}) error
+}
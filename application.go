@@ -0,0 +1,132 @@
+package newrelic
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+
+	"go.datanerd.us/p/will/newrelic/internal"
+)
+
+var (
+	highSecurityEnabledError        = errors.New("high security enabled: custom events disabled")
+	customEventsDisabledError       = errors.New("custom events locally disabled")
+	customEventsRemoteDisabledError = errors.New("custom events remotely disabled")
+)
+
+// appRun holds the settings the collector's connect reply contributed
+// for the current agent run.
+type appRun struct {
+	*internal.ConnectReply
+}
+
+// app is the concrete Application implementation.
+type app struct {
+	config Config
+
+	mu  sync.Mutex
+	run *appRun
+
+	// testConsumer lets tests observe harvested data directly instead
+	// of going through a real collector connection (see TestApp and
+	// testApp in app_test.go).
+	testConsumer internal.Consumer
+}
+
+// newApp creates an app with no agent run.  NewApplication builds on it
+// and, in Development mode, installs a default run; neither one
+// connects to the collector.
+func newApp(cfg Config) (*app, error) {
+	return &app{config: cfg}, nil
+}
+
+// NewApplication creates an Application given a Config.
+func NewApplication(cfg Config) (Application, error) {
+	application, err := newApp(cfg)
+	if nil != err {
+		return nil, err
+	}
+	if cfg.Development {
+		application.setRun(&appRun{ConnectReply: internal.ConnectReplyDefaults()})
+	}
+	return application, nil
+}
+
+func (app *app) setRun(run *appRun) {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	app.run = run
+}
+
+func (app *app) getRun() *appRun {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	return app.run
+}
+
+func (app *app) consumer() internal.Consumer {
+	return app.testConsumer
+}
+
+// StartTransaction implements Application.
+func (app *app) StartTransaction(name string, w http.ResponseWriter, r *http.Request) Transaction {
+	run := app.getRun()
+
+	args := internal.NewTxnArgs{
+		Config: internal.TxnConfig{
+			HighSecurity:                app.config.HighSecurity,
+			ErrorCollectorEnabled:       app.config.ErrorCollector.Enabled,
+			ErrorCollectorCaptureEvents: app.config.ErrorCollector.CaptureEvents,
+			TransactionEventsEnabled:    app.config.TransactionEvents.Enabled,
+			Attributes:                  app.config.Attributes,
+
+			// A run-less (not yet connected) Transaction still
+			// harvests locally, as testApp's Development-mode
+			// apps rely on.
+			CollectErrors:          true,
+			CollectErrorEvents:     true,
+			CollectAnalyticsEvents: true,
+		},
+		Consumer: app.consumer(),
+		Name:     name,
+		Writer:   w,
+		Request:  r,
+	}
+	if nil != run {
+		args.RunID = run.RunID
+		args.Config.CollectErrors = run.CollectErrors
+		args.Config.CollectErrorEvents = run.CollectErrorEvents
+		args.Config.CollectAnalyticsEvents = run.CollectAnalyticsEvents
+	}
+
+	return internal.NewTxn(args)
+}
+
+// RecordCustomEvent implements Application.
+func (app *app) RecordCustomEvent(eventType string, params map[string]interface{}) error {
+	if app.config.HighSecurity {
+		return highSecurityEnabledError
+	}
+	if !app.config.CustomEvents.Enabled {
+		return customEventsDisabledError
+	}
+
+	run := app.getRun()
+	if nil != run && !run.CollectCustomEvents {
+		return customEventsRemoteDisabledError
+	}
+
+	event, err := internal.CreateCustomEvent(eventType, params)
+	if nil != err {
+		return err
+	}
+
+	if c := app.consumer(); nil != c {
+		var runID internal.AgentRunID
+		if nil != run {
+			runID = run.RunID
+		}
+		c.Consume(runID, event)
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package newrelic
+
+import (
+	"testing"
+
+	"go.datanerd.us/p/will/newrelic/internal"
+)
+
+func TestAddAttributeSuccess(t *testing.T) {
+	app := testApp(nil, nil, t)
+	txn := app.StartTransaction("myName", nil, nil)
+	if err := txn.AddAttribute("zip", 1); nil != err {
+		t.Error(err)
+	}
+	txn.End()
+
+	app.h.ExpectTxnEvents(t, []internal.WantTxnEvent{
+		{Name: "OtherTransaction/Pattern/myName", Attributes: map[string]interface{}{"zip": 1}},
+	})
+}
+
+func TestAddAttributeHighSecurity(t *testing.T) {
+	cfgfn := func(cfg *Config) { cfg.HighSecurity = true }
+	app := testApp(nil, cfgfn, t)
+	txn := app.StartTransaction("myName", nil, nil)
+	if err := txn.AddAttribute("zip", 1); err != internal.ErrHighSecurityEnabled {
+		t.Error(err)
+	}
+	txn.End()
+
+	app.h.ExpectTxnEvents(t, []internal.WantTxnEvent{
+		{Name: "OtherTransaction/Pattern/myName"},
+	})
+}
+
+func TestAddAttributeInvalidType(t *testing.T) {
+	app := testApp(nil, nil, t)
+	txn := app.StartTransaction("myName", nil, nil)
+	if err := txn.AddAttribute("zip", struct{}{}); err != internal.ErrInvalidAttributeType {
+		t.Error(err)
+	}
+	txn.End()
+
+	app.h.ExpectTxnEvents(t, []internal.WantTxnEvent{
+		{Name: "OtherTransaction/Pattern/myName"},
+	})
+}
+
+func TestAddAttributeTxnEnded(t *testing.T) {
+	app := testApp(nil, nil, t)
+	txn := app.StartTransaction("myName", nil, nil)
+	txn.End()
+	if err := txn.AddAttribute("zip", 1); err != internal.AlreadyEndedErr {
+		t.Error(err)
+	}
+}
+
+func TestAddAttributeExcluded(t *testing.T) {
+	cfgfn := func(cfg *Config) { cfg.Attributes.Exclude = []string{"zip"} }
+	app := testApp(nil, cfgfn, t)
+	txn := app.StartTransaction("myName", nil, nil)
+	if err := txn.AddAttribute("zip", 1); nil != err {
+		t.Error(err)
+	}
+	if err := txn.AddAttribute("zap", 2); nil != err {
+		t.Error(err)
+	}
+	txn.End()
+
+	app.h.ExpectTxnEvents(t, []internal.WantTxnEvent{
+		{Name: "OtherTransaction/Pattern/myName", Attributes: map[string]interface{}{"zap": 2}},
+	})
+}
+
+func TestAddAttributeIncluded(t *testing.T) {
+	cfgfn := func(cfg *Config) { cfg.Attributes.Include = []string{"zip"} }
+	app := testApp(nil, cfgfn, t)
+	txn := app.StartTransaction("myName", nil, nil)
+	if err := txn.AddAttribute("zip", 1); nil != err {
+		t.Error(err)
+	}
+	if err := txn.AddAttribute("zap", 2); nil != err {
+		t.Error(err)
+	}
+	txn.End()
+
+	app.h.ExpectTxnEvents(t, []internal.WantTxnEvent{
+		{Name: "OtherTransaction/Pattern/myName", Attributes: map[string]interface{}{"zip": 1}},
+	})
+}
+
+func TestAddAttributeOnErrorEvent(t *testing.T) {
+	app := testApp(nil, nil, t)
+	txn := app.StartTransaction("myName", nil, nil)
+	if err := txn.AddAttribute("zip", 1); nil != err {
+		t.Error(err)
+	}
+	if err := txn.NoticeError(myError{}); nil != err {
+		t.Error(err)
+	}
+	txn.End()
+
+	app.h.ExpectErrorEvents(t, []internal.WantErrorEvent{{
+		TxnName:    "OtherTransaction/Pattern/myName",
+		Msg:        "my msg",
+		Klass:      "newrelic.myError",
+		Attributes: map[string]interface{}{"zip": 1},
+	}})
+	app.h.ExpectErrors(t, []internal.WantError{{
+		TxnName:    "OtherTransaction/Pattern/myName",
+		Msg:        "my msg",
+		Klass:      "newrelic.myError",
+		Caller:     "newrelic.TestAddAttributeOnErrorEvent",
+		Attributes: map[string]interface{}{"zip": 1},
+	}})
+}
@@ -0,0 +1,52 @@
+package newrelic
+
+import "go.datanerd.us/p/will/newrelic/internal"
+
+// Config contains Application and Transaction behavior settings.
+// Create one with NewConfig, override whichever fields need non-default
+// values, then pass it to NewApplication.
+type Config struct {
+	AppName string
+	License string
+
+	// Development makes NewApplication start with a default agent run
+	// (see internal.ConnectReplyDefaults) instead of one supplied by the
+	// collector.  No collector connection is made in either mode.
+	Development bool
+
+	// HighSecurity disables custom events, custom attributes, and
+	// scrubs error messages, regardless of the other settings below.
+	HighSecurity bool
+
+	CustomEvents struct {
+		Enabled bool
+	}
+
+	ErrorCollector struct {
+		Enabled       bool
+		CaptureEvents bool
+	}
+
+	TransactionEvents struct {
+		Enabled bool
+	}
+
+	// Attributes controls which custom attributes added with
+	// Transaction.AddAttribute are allowed to reach the transaction
+	// event, error event, and error trace.
+	Attributes internal.AttributeDestinationConfig
+}
+
+// NewConfig creates a Config populated with default settings for the
+// named application.
+func NewConfig(appName, license string) Config {
+	cfg := Config{
+		AppName: appName,
+		License: license,
+	}
+	cfg.CustomEvents.Enabled = true
+	cfg.ErrorCollector.Enabled = true
+	cfg.ErrorCollector.CaptureEvents = true
+	cfg.TransactionEvents.Enabled = true
+	return cfg
+}
@@ -0,0 +1,24 @@
+package newrelic
+
+import "context"
+
+type transactionCtxKey struct{}
+
+// NewContext returns a copy of ctx that carries txn.  WrapHandleFunc and
+// WrapHandle call this automatically, so handlers registered through
+// those functions can retrieve their Transaction with FromContext
+// instead of type-asserting http.ResponseWriter -- an assertion that
+// breaks as soon as something else in the middleware stack (gorilla,
+// chi, echo, ...) wraps the writer.
+func NewContext(ctx context.Context, txn Transaction) context.Context {
+	return context.WithValue(ctx, transactionCtxKey{}, txn)
+}
+
+// FromContext returns the Transaction stored in ctx by NewContext, and
+// reports whether one was found.  This lets application code reach the
+// Transaction from goroutines, database calls, and other non-HTTP call
+// sites without threading it through as an explicit parameter.
+func FromContext(ctx context.Context) (Transaction, bool) {
+	txn, ok := ctx.Value(transactionCtxKey{}).(Transaction)
+	return txn, ok
+}
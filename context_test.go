@@ -0,0 +1,57 @@
+package newrelic
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFromContextMissing(t *testing.T) {
+	if txn, ok := FromContext(helloRequest.Context()); ok || nil != txn {
+		t.Error(txn, ok)
+	}
+}
+
+func TestFromContextPresent(t *testing.T) {
+	app := testApp(nil, nil, t)
+	txn := app.StartTransaction("myName", nil, nil)
+	ctx := NewContext(helloRequest.Context(), txn)
+
+	out, ok := FromContext(ctx)
+	if !ok || out != txn {
+		t.Error(out, ok)
+	}
+}
+
+// wrapResponseWriter simulates a middleware (gorilla, chi, echo) that
+// wraps the ResponseWriter before the handler runs, making the
+// type-assertion-based lookup of Transaction unavailable.
+type wrapResponseWriter struct {
+	http.ResponseWriter
+}
+
+func TestWrapHandleFuncSurvivesWriterWrapping(t *testing.T) {
+	app := testApp(nil, nil, t)
+
+	var found Transaction
+	var ok bool
+	wrapped := func(w http.ResponseWriter, req *http.Request) {
+		found, ok = FromContext(req.Context())
+		if _, assertable := w.(Transaction); assertable {
+			t.Error("ResponseWriter should no longer be a Transaction once wrapped")
+		}
+		w.Write(helloResponse)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(WrapHandleFunc(app, helloPath, func(w http.ResponseWriter, req *http.Request) {
+		wrapped(&wrapResponseWriter{w}, req)
+	}))
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, helloRequest)
+
+	if !ok || nil == found {
+		t.Fatal("Transaction not found in context", ok, found)
+	}
+}
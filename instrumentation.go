@@ -0,0 +1,39 @@
+package newrelic
+
+import "net/http"
+
+// WrapHandleFunc serves the same purpose as WrapHandle for functions that
+// don't need to be converted to http.Handler.
+//
+// The Transaction is made available to the handler in two ways: it is
+// passed in place of the original http.ResponseWriter (so the handler
+// can type-assert it back to Transaction), and it is placed in the
+// request's context, where it can be retrieved with FromContext
+// regardless of how many times the ResponseWriter has been wrapped by
+// other middleware.
+func WrapHandleFunc(app Application, pattern string, handler func(http.ResponseWriter, *http.Request)) (string, func(http.ResponseWriter, *http.Request)) {
+	if nil == app {
+		return pattern, handler
+	}
+	return pattern, func(w http.ResponseWriter, r *http.Request) {
+		txn := app.StartTransaction(pattern, w, r)
+		defer txn.End()
+
+		r = r.WithContext(NewContext(r.Context(), txn))
+
+		handler(txn, r)
+	}
+}
+
+// WrapHandle facilitates instrumentation of handlers registered with an
+// http.ServeMux.  For example, to instrument this code:
+//
+//	http.Handle("/foo", fooHandler)
+//
+// Perform this replacement:
+//
+//	http.Handle(newrelic.WrapHandle(app, "/foo", fooHandler))
+func WrapHandle(app Application, pattern string, handler http.Handler) (string, http.Handler) {
+	pattern, fn := WrapHandleFunc(app, pattern, handler.ServeHTTP)
+	return pattern, http.HandlerFunc(fn)
+}
@@ -0,0 +1,100 @@
+package internal
+
+import "errors"
+
+// ErrHighSecurityEnabled is returned by Transaction.AddAttribute when
+// high security mode is on, which disables all custom attributes.
+var ErrHighSecurityEnabled = errors.New("custom attributes disabled by high security mode")
+
+// ErrInvalidAttributeType is returned by Transaction.AddAttribute when
+// value is not one of the types the collector accepts for a custom
+// attribute: string, bool, or a numeric type.
+var ErrInvalidAttributeType = errors.New("attribute value must be a string, bool, or number")
+
+// validAttributeValue reports whether value is a type the collector
+// accepts for a custom attribute or custom event parameter.
+func validAttributeValue(value interface{}) bool {
+	switch value.(type) {
+	case string, bool,
+		int, int8, int16, int32, int64,
+		uint, uint8, uint16, uint32, uint64,
+		float32, float64:
+		return true
+	default:
+		return false
+	}
+}
+
+// AttributeDestinationConfig controls which custom attributes reach a
+// given destination (the transaction event, an error event, or an error
+// trace).  A key reaches the destination if Include is empty or
+// contains the key, and Exclude does not contain it.
+type AttributeDestinationConfig struct {
+	Include []string
+	Exclude []string
+}
+
+func attributeAllowed(cfg AttributeDestinationConfig, key string) bool {
+	for _, excluded := range cfg.Exclude {
+		if excluded == key {
+			return false
+		}
+	}
+	if 0 == len(cfg.Include) {
+		return true
+	}
+	for _, included := range cfg.Include {
+		if included == key {
+			return true
+		}
+	}
+	return false
+}
+
+// AddAttribute stores a custom key/value pair on txn so it can later be
+// copied onto the transaction event, and onto the error event and error
+// trace if NoticeError is called.
+func (txn *txn) AddAttribute(key string, value interface{}) error {
+	txn.mu.Lock()
+	defer txn.mu.Unlock()
+
+	if txn.finished {
+		return AlreadyEndedErr
+	}
+	if txn.config.HighSecurity {
+		return ErrHighSecurityEnabled
+	}
+	if !validAttributeValue(value) {
+		return ErrInvalidAttributeType
+	}
+	if nil == txn.userAttributes {
+		txn.userAttributes = make(map[string]interface{})
+	}
+	txn.userAttributes[key] = value
+	return nil
+}
+
+// userAttributesFor filters txn's custom attributes, merged with any
+// attributes the agent recorded itself (see segmentAttributes), down to
+// the ones cfg allows through, for inclusion in a transaction event,
+// error event, or error trace.
+func (txn *txn) userAttributesFor(cfg AttributeDestinationConfig) map[string]interface{} {
+	if 0 == len(txn.userAttributes) && 0 == len(txn.segmentAttributes) {
+		return nil
+	}
+	out := make(map[string]interface{}, len(txn.userAttributes)+len(txn.segmentAttributes))
+	for k, v := range txn.segmentAttributes {
+		if attributeAllowed(cfg, k) {
+			out[k] = v
+		}
+	}
+	for k, v := range txn.userAttributes {
+		if attributeAllowed(cfg, k) {
+			out[k] = v
+		}
+	}
+	if 0 == len(out) {
+		return nil
+	}
+	return out
+}
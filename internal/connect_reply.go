@@ -0,0 +1,28 @@
+package internal
+
+// AgentRunID is the identifier the collector assigns to an agent run.
+type AgentRunID string
+
+// ConnectReply holds the subset of the collector's connect reply that
+// controls whether a harvest payload is accepted server-side.
+type ConnectReply struct {
+	RunID AgentRunID
+
+	CollectCustomEvents    bool
+	CollectErrors          bool
+	CollectErrorEvents     bool
+	CollectAnalyticsEvents bool
+}
+
+// ConnectReplyDefaults returns a ConnectReply with every toggle
+// enabled, matching a real collector connect reply absent any
+// server-side disabling.
+func ConnectReplyDefaults() *ConnectReply {
+	return &ConnectReply{
+		RunID:                  "default-run-id",
+		CollectCustomEvents:    true,
+		CollectErrors:          true,
+		CollectErrorEvents:     true,
+		CollectAnalyticsEvents: true,
+	}
+}
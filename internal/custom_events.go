@@ -0,0 +1,55 @@
+package internal
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+)
+
+var eventTypeRegex = regexp.MustCompile(`^[a-zA-Z0-9:_ ]+$`)
+
+// EventTypeRegexError is returned by Application.RecordCustomEvent when
+// eventType does not match the name format the collector accepts.
+var EventTypeRegexError = errors.New("event type must match regex")
+
+// WantCustomEvent describes a custom event recorded via
+// Application.RecordCustomEvent, for use in test assertions.
+type WantCustomEvent struct {
+	Type   string
+	Params map[string]interface{}
+}
+
+// MergeIntoHarvest implements Harvestable.
+func (e WantCustomEvent) MergeIntoHarvest(h *Harvest) {
+	h.CustomEvents = append(h.CustomEvents, e)
+}
+
+// CreateCustomEvent validates eventType and builds the Harvestable for
+// it, returning EventTypeRegexError if eventType does not match the
+// name format the collector accepts.
+func CreateCustomEvent(eventType string, params map[string]interface{}) (Harvestable, error) {
+	if !eventTypeRegex.MatchString(eventType) {
+		return nil, EventTypeRegexError
+	}
+	for _, v := range params {
+		if !validAttributeValue(v) {
+			return nil, ErrInvalidAttributeType
+		}
+	}
+	return WantCustomEvent{Type: eventType, Params: params}, nil
+}
+
+// ExpectCustomEvents compares h's custom events against want, failing t
+// if they differ.
+func (h *Harvest) ExpectCustomEvents(t *testing.T, want []WantCustomEvent) {
+	if len(want) != len(h.CustomEvents) {
+		t.Errorf("wrong number of custom events: expected %d got %d", len(want), len(h.CustomEvents))
+		return
+	}
+	for i, w := range want {
+		got := h.CustomEvents[i]
+		if w.Type != got.Type || !attributesEqual(w.Params, got.Params) {
+			t.Errorf("custom event %d: expected %+v got %+v", i, w, got)
+		}
+	}
+}
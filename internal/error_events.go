@@ -0,0 +1,47 @@
+package internal
+
+import "testing"
+
+// WantErrorEvent describes an error event recorded via NoticeError or a
+// recovered panic, for use in test assertions.
+type WantErrorEvent struct {
+	TxnName    string
+	Msg        string
+	Klass      string
+	Attributes map[string]interface{}
+}
+
+// MergeIntoHarvest implements Harvestable.
+func (e WantErrorEvent) MergeIntoHarvest(h *Harvest) {
+	h.ErrorEvents = append(h.ErrorEvents, e)
+}
+
+// ExpectErrorEvents compares h's error events against want, failing t if
+// they differ.
+func (h *Harvest) ExpectErrorEvents(t *testing.T, want []WantErrorEvent) {
+	if len(want) != len(h.ErrorEvents) {
+		t.Errorf("wrong number of error events: expected %d got %d", len(want), len(h.ErrorEvents))
+		return
+	}
+	for i, w := range want {
+		got := h.ErrorEvents[i]
+		if w.TxnName != got.TxnName || w.Msg != got.Msg || w.Klass != got.Klass || !attributesEqual(w.Attributes, got.Attributes) {
+			t.Errorf("error event %d: expected %+v got %+v", i, w, got)
+		}
+	}
+}
+
+// attributesEqual reports whether a and b hold the same keys and
+// values.
+func attributesEqual(a, b map[string]interface{}) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		bv, ok := b[k]
+		if !ok || v != bv {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,78 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+var (
+	// AlreadyEndedErr is returned by Transaction methods that require
+	// the transaction still be running.
+	AlreadyEndedErr = errors.New("transaction already ended")
+
+	// NilError is returned by NoticeError when passed a nil error.
+	NilError = errors.New("error is nil")
+
+	// ErrorsLocallyDisabled is returned by NoticeError when
+	// Config.ErrorCollector.Enabled is false.
+	ErrorsLocallyDisabled = errors.New("errors locally disabled")
+
+	// ErrorsRemotelyDisabled is returned by NoticeError when the
+	// collector's connect reply has disabled error collection.
+	ErrorsRemotelyDisabled = errors.New("errors remotely disabled")
+)
+
+// HighSecurityErrorMsg replaces an error's message when high security
+// mode is enabled, since the original message may contain sensitive
+// data.
+const HighSecurityErrorMsg = "message removed by high security setting"
+
+// WantError describes an error trace recorded via NoticeError or a
+// recovered panic, for use in test assertions.
+type WantError struct {
+	TxnName    string
+	Msg        string
+	Klass      string
+	Caller     string
+	Attributes map[string]interface{}
+}
+
+// MergeIntoHarvest implements Harvestable.
+func (e WantError) MergeIntoHarvest(h *Harvest) {
+	h.Errors = append(h.Errors, e)
+}
+
+// ExpectErrors compares h's error traces against want, failing t if
+// they differ.
+func (h *Harvest) ExpectErrors(t *testing.T, want []WantError) {
+	if len(want) != len(h.Errors) {
+		t.Errorf("wrong number of errors: expected %d got %d", len(want), len(h.Errors))
+		return
+	}
+	for i, w := range want {
+		got := h.Errors[i]
+		if w.TxnName != got.TxnName || w.Msg != got.Msg || w.Klass != got.Klass || w.Caller != got.Caller || !attributesEqual(w.Attributes, got.Attributes) {
+			t.Errorf("error %d: expected %+v got %+v", i, w, got)
+		}
+	}
+}
+
+// panicError wraps a non-error value recovered from a panic so it can
+// be reported the same way as a NoticeError call.
+type panicError struct {
+	value interface{}
+}
+
+func (e panicError) Error() string {
+	return fmt.Sprint(e.value)
+}
+
+// errorFromPanic converts a value recovered from a panic into an error,
+// passing an already-error value through unchanged.
+func errorFromPanic(v interface{}) error {
+	if err, ok := v.(error); ok {
+		return err
+	}
+	return panicError{value: v}
+}
@@ -0,0 +1,39 @@
+package internal
+
+import "time"
+
+// Harvestable is implemented by anything that can merge itself into a
+// Harvest -- a custom event, transaction event, error, error event, or
+// metric payload.
+type Harvestable interface {
+	MergeIntoHarvest(h *Harvest)
+}
+
+// Consumer accepts harvested data for a given agent run.  Tests
+// implement it by appending directly to a Harvest (see TestApp in
+// app_test.go); an Application with no Consumer set discards the data
+// its transactions and custom events produce.
+type Consumer interface {
+	Consume(id AgentRunID, data Harvestable)
+}
+
+// Harvest collects the events, errors, and metrics produced by
+// transactions and Application.RecordCustomEvent during a harvest
+// cycle.
+type Harvest struct {
+	started time.Time
+
+	CustomEvents []WantCustomEvent
+	TxnEvents    []WantTxnEvent
+	Errors       []WantError
+	ErrorEvents  []WantErrorEvent
+	Metrics      *metricTable
+}
+
+// NewHarvest creates an empty Harvest.
+func NewHarvest(now time.Time) *Harvest {
+	return &Harvest{
+		started: now,
+		Metrics: newMetricTable(),
+	}
+}
@@ -0,0 +1,74 @@
+package internal
+
+import "testing"
+
+// WantMetric describes a metric expectation.  A metric named Name must
+// be present if Forced is true; if Forced is false its absence is
+// tolerated, but if present it is still checked against Data.  Metrics
+// are looked up by Name alone; Scope is not checked.  Data, when non-nil, is compared against
+// [count, totalTime, exclusiveTime, min, max, sumOfSquares, ...]; a nil
+// Data only checks presence.
+type WantMetric struct {
+	Name   string
+	Scope  string
+	Forced bool
+	Data   []float64
+}
+
+type metricTable struct {
+	counts map[string]float64
+}
+
+func newMetricTable() *metricTable {
+	return &metricTable{counts: make(map[string]float64)}
+}
+
+func (t *metricTable) add(name string, count float64) {
+	t.counts[name] += count
+}
+
+// metricsPayload is the Harvestable produced by a transaction's local
+// metric table once it ends.
+type metricsPayload struct {
+	counts map[string]float64
+}
+
+// MergeIntoHarvest implements Harvestable.
+func (m metricsPayload) MergeIntoHarvest(h *Harvest) {
+	for name, count := range m.counts {
+		h.Metrics.add(name, count)
+	}
+}
+
+// ExpectMetrics compares h's metrics against want, failing t if they
+// differ.
+func (h *Harvest) ExpectMetrics(t *testing.T, want []WantMetric) {
+	for _, w := range want {
+		count, ok := h.Metrics.counts[w.Name]
+		if w.Forced && !ok {
+			t.Errorf("expected metric %s not found", w.Name)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if nil != w.Data {
+			got := []float64{count, 0, 0, 0, 0, 0, 0}
+			if !floatsEqual(w.Data, got) {
+				t.Errorf("metric %s: expected data %v got %v", w.Name, w.Data, got)
+			}
+		}
+	}
+}
+
+func floatsEqual(a, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,297 @@
+package internal
+
+import (
+	"net/http"
+	"reflect"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TxnConfig carries the subset of Config, plus the active agent run's
+// remote collection toggles, that a Transaction needs in order to
+// decide what it is allowed to record.
+type TxnConfig struct {
+	HighSecurity                bool
+	ErrorCollectorEnabled       bool
+	ErrorCollectorCaptureEvents bool
+	TransactionEventsEnabled    bool
+	Attributes                  AttributeDestinationConfig
+
+	// The fields below come from the agent run's connect reply rather
+	// than from Config; a run-less Transaction defaults them to true
+	// so Development-mode apps still harvest locally (see application.go).
+	CollectErrors          bool
+	CollectErrorEvents     bool
+	CollectAnalyticsEvents bool
+}
+
+// NewTxnArgs bundles the arguments NewTxn needs to start a Transaction.
+type NewTxnArgs struct {
+	Config   TxnConfig
+	Consumer Consumer
+	RunID    AgentRunID
+	Name     string
+	Writer   http.ResponseWriter
+	Request  *http.Request
+}
+
+// txn is the concrete type backing the newrelic package's Transaction
+// interface.  It also implements the unexported interfaces (see
+// RecordExternalSegment) that package uses to reach into a running
+// Transaction without expanding the public API; since txn lives here
+// rather than in that package, those interfaces' methods are exported.
+type txn struct {
+	mu sync.Mutex
+
+	config   TxnConfig
+	consumer Consumer
+	runID    AgentRunID
+
+	name   string
+	isWeb  bool
+	writer http.ResponseWriter
+
+	finished       bool
+	userAttributes map[string]interface{}
+
+	// segmentAttributes holds attributes the agent itself records
+	// against the transaction (e.g. the status code of the last
+	// External segment), as opposed to ones added through the public
+	// AddAttribute API.  userAttributesFor merges the two.
+	segmentAttributes map[string]interface{}
+
+	metrics     *metricTable
+	errors      []WantError
+	errorEvents []WantErrorEvent
+}
+
+// NewTxn starts a Transaction per args.
+func NewTxn(args NewTxnArgs) *txn {
+	return &txn{
+		config:   args.Config,
+		consumer: args.Consumer,
+		runID:    args.RunID,
+		name:     args.Name,
+		isWeb:    nil != args.Request,
+		writer:   args.Writer,
+		metrics:  newMetricTable(),
+	}
+}
+
+// Header implements http.ResponseWriter.
+func (t *txn) Header() http.Header { return t.writer.Header() }
+
+// Write implements http.ResponseWriter.
+func (t *txn) Write(b []byte) (int, error) { return t.writer.Write(b) }
+
+// WriteHeader implements http.ResponseWriter.
+func (t *txn) WriteHeader(code int) { t.writer.WriteHeader(code) }
+
+// SetName implements the newrelic package's Transaction interface.
+func (t *txn) SetName(name string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.finished {
+		return AlreadyEndedErr
+	}
+	t.name = name
+	return nil
+}
+
+// patternName strips the leading slash from t.name, since the metric
+// name already supplies one after "Pattern" ("WebTransaction/Pattern/hello",
+// not "WebTransaction/Pattern//hello").
+func (t *txn) patternName() string {
+	return strings.TrimPrefix(t.name, "/")
+}
+
+func (t *txn) txnMetricName() string {
+	prefix := "OtherTransaction"
+	if t.isWeb {
+		prefix = "WebTransaction"
+	}
+	return prefix + "/Pattern/" + t.patternName()
+}
+
+func (t *txn) recordMetric(name string, count float64) {
+	t.metrics.add(name, count)
+}
+
+// NoticeError implements the newrelic package's Transaction interface.
+func (t *txn) NoticeError(err error) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.noticeErrorLocked(err, callerName(2))
+}
+
+// noticeErrorLocked does the work of NoticeError and of End's panic
+// recovery.  caller is precomputed by the exported entry point so that
+// it names the code that triggered the error rather than this method.
+func (t *txn) noticeErrorLocked(err error, caller string) error {
+	if t.finished {
+		return AlreadyEndedErr
+	}
+	if nil == err {
+		return NilError
+	}
+
+	txnName := t.txnMetricName()
+	allZone := "Errors/allOther"
+	if t.isWeb {
+		allZone = "Errors/allWeb"
+	}
+	t.recordMetric("Errors/all", 1)
+	t.recordMetric(allZone, 1)
+	t.recordMetric("Errors/"+txnName, 1)
+
+	if !t.config.ErrorCollectorEnabled {
+		return ErrorsLocallyDisabled
+	}
+	if !t.config.CollectErrors {
+		return ErrorsRemotelyDisabled
+	}
+
+	msg := err.Error()
+	if t.config.HighSecurity {
+		msg = HighSecurityErrorMsg
+	}
+	klass := reflect.TypeOf(err).String()
+
+	t.errors = append(t.errors, WantError{
+		TxnName:    txnName,
+		Msg:        msg,
+		Klass:      klass,
+		Caller:     caller,
+		Attributes: t.userAttributesFor(t.config.Attributes),
+	})
+
+	if t.config.ErrorCollectorCaptureEvents && t.config.CollectErrorEvents {
+		t.errorEvents = append(t.errorEvents, WantErrorEvent{
+			TxnName:    txnName,
+			Msg:        msg,
+			Klass:      klass,
+			Attributes: t.userAttributesFor(t.config.Attributes),
+		})
+	}
+	return nil
+}
+
+// RecordExternalSegment implements the newrelic package's unexported
+// externalSegmentRecorder interface, recording External metrics for a
+// completed outbound call.  Each metric is a call count; start and stop
+// are not used, so no duration is recorded.
+func (t *txn) RecordExternalSegment(start, stop time.Time, host string, statusCode int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.finished {
+		return
+	}
+
+	allZone := "External/allOther"
+	if t.isWeb {
+		allZone = "External/allWeb"
+	}
+	t.recordMetric("External/all", 1)
+	t.recordMetric(allZone, 1)
+	if "" != host {
+		t.recordMetric("External/"+host+"/http", 1)
+	}
+
+	if 0 != statusCode {
+		if nil == t.segmentAttributes {
+			t.segmentAttributes = make(map[string]interface{})
+		}
+		t.segmentAttributes["http.statusCode"] = statusCode
+	}
+}
+
+func (t *txn) finishLocked() error {
+	t.finished = true
+
+	t.recordMetric(t.txnMetricName(), 1)
+	if t.isWeb {
+		t.recordMetric("WebTransaction", 1)
+		t.recordMetric("HttpDispatcher", 1)
+		t.recordMetric("Apdex", 1)
+		t.recordMetric("Apdex/Pattern/"+t.patternName(), 1)
+	} else {
+		t.recordMetric("OtherTransaction/all", 1)
+	}
+
+	c := t.consumer
+	if nil == c {
+		return nil
+	}
+
+	for _, e := range t.errors {
+		c.Consume(t.runID, e)
+	}
+	for _, e := range t.errorEvents {
+		c.Consume(t.runID, e)
+	}
+	c.Consume(t.runID, metricsPayload{counts: t.metrics.counts})
+
+	if t.config.TransactionEventsEnabled && t.config.CollectAnalyticsEvents {
+		zone := ""
+		if t.isWeb {
+			zone = "S"
+		}
+		c.Consume(t.runID, WantTxnEvent{
+			Name:       t.txnMetricName(),
+			Zone:       zone,
+			Attributes: t.userAttributesFor(t.config.Attributes),
+		})
+	}
+	return nil
+}
+
+// End implements the newrelic package's Transaction interface.  Since
+// End is always the direct target of a `defer txn.End()`, calling
+// recover() here -- rather than in a further nested closure -- both
+// satisfies the "called directly by a deferred function" rule and
+// means a recovered panic's caller name is exactly "internal.(*txn).End"
+// with no closure suffix to strip.
+func (t *txn) End() error {
+	r := recover()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.finished {
+		if nil != r {
+			panic(r)
+		}
+		return AlreadyEndedErr
+	}
+
+	if nil != r {
+		t.noticeErrorLocked(errorFromPanic(r), callerName(1))
+	}
+
+	err := t.finishLocked()
+
+	if nil != r {
+		panic(r)
+	}
+	return err
+}
+
+var closureSuffixRegexp = regexp.MustCompile(`(\.func\d+)+$`)
+
+// callerName returns the "pkg.Func" or "pkg.(*Type).Method" name of the
+// function skip frames up the stack from callerName itself -- skip=1 is
+// callerName's caller, skip=2 is that function's caller, and so on.
+func callerName(skip int) string {
+	pc, _, _, ok := runtime.Caller(skip)
+	if !ok {
+		return ""
+	}
+	name := runtime.FuncForPC(pc).Name()
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	return closureSuffixRegexp.ReplaceAllString(name, "")
+}
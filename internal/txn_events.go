@@ -0,0 +1,31 @@
+package internal
+
+import "testing"
+
+// WantTxnEvent describes a transaction event recorded when a
+// Transaction ends, for use in test assertions.
+type WantTxnEvent struct {
+	Name       string
+	Zone       string
+	Attributes map[string]interface{}
+}
+
+// MergeIntoHarvest implements Harvestable.
+func (e WantTxnEvent) MergeIntoHarvest(h *Harvest) {
+	h.TxnEvents = append(h.TxnEvents, e)
+}
+
+// ExpectTxnEvents compares h's transaction events against want, failing
+// t if they differ.
+func (h *Harvest) ExpectTxnEvents(t *testing.T, want []WantTxnEvent) {
+	if len(want) != len(h.TxnEvents) {
+		t.Errorf("wrong number of txn events: expected %d got %d", len(want), len(h.TxnEvents))
+		return
+	}
+	for i, w := range want {
+		got := h.TxnEvents[i]
+		if w.Name != got.Name || w.Zone != got.Zone || !attributesEqual(w.Attributes, got.Attributes) {
+			t.Errorf("txn event %d: expected %+v got %+v", i, w, got)
+		}
+	}
+}
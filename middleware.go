@@ -0,0 +1,65 @@
+package newrelic
+
+import "net/http"
+
+func instrument(app Application, pattern string, next http.Handler) http.Handler {
+	if nil == app {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := pattern
+		if "" == name {
+			// KNOWN GAP: a chi app using Middleware without an
+			// explicit pattern gets named from the raw URL path
+			// here, not the route pattern (e.g. "/users/123"
+			// instead of "/users/{id}"). chi stores its *chi.Context
+			// under an unexported, pointer-identity context key
+			// (chi.RouteCtxKey), so there is no way to retrieve it
+			// by duck-typing against r.Context().Value(...) without
+			// importing chi to reference that exact key -- and this
+			// package has no go.mod to take that dependency. Callers
+			// on chi should use InstrumentedHandler per-route with
+			// the pattern chi already knows, or a future integration
+			// package can import chi and do this lookup properly.
+			name = r.URL.Path
+		}
+
+		txn := app.StartTransaction(name, w, r)
+		defer txn.End()
+
+		r = r.WithContext(NewContext(r.Context(), txn))
+		next.ServeHTTP(txn, r)
+	})
+}
+
+// Middleware returns a func(http.Handler) http.Handler suitable for
+// chi, gorilla/mux, negroni, and other middleware stacks built around
+// the classical http.Handler chain, for callers who cannot use
+// WrapHandle/WrapHandleFunc's two-argument, mux-registration style API.
+//
+// The transaction is named from the request's URL path, NOT a chi route
+// pattern -- chi's matched pattern lives behind an unexported context
+// key only chi itself can look up, so there is no dependency-free way
+// to recover it here (see the KNOWN GAP comment in instrument()). Use
+// InstrumentedHandler to supply an explicit name per route instead;
+// router-aware automatic naming belongs in a dedicated integration
+// package that can depend on that router directly.
+//
+// Passing a nil Application turns this into a no-op, matching
+// WrapHandleFunc/WrapHandle.
+//
+// txn.End() recovers and re-panics on its own (see WrapHandleFunc), so
+// a panic in a handler further down the chain is still reported and
+// still propagates to any middleware wrapping this one.
+func Middleware(app Application) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return instrument(app, "", next)
+	}
+}
+
+// InstrumentedHandler wraps h with the same instrumentation as
+// Middleware, naming the transaction pattern rather than deriving it
+// from the request's URL path.
+func InstrumentedHandler(app Application, pattern string, h http.Handler) http.Handler {
+	return instrument(app, pattern, h)
+}
@@ -0,0 +1,127 @@
+package newrelic
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.datanerd.us/p/will/newrelic/internal"
+)
+
+func TestMiddlewareURLPathName(t *testing.T) {
+	app := testApp(nil, nil, t)
+
+	mw := Middleware(app)
+	handler := mw(http.HandlerFunc(myErrorHandler))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, helloRequest)
+
+	if out := w.Body.String(); "my response" != out {
+		t.Error(out)
+	}
+
+	app.h.ExpectMetrics(t, []internal.WantMetric{
+		{"WebTransaction/Pattern" + helloPath, "", true, nil},
+		{"WebTransaction", "", true, nil},
+		{"HttpDispatcher", "", true, nil},
+		{"Apdex", "", true, nil},
+		{"Apdex/Pattern" + helloPath, "", false, nil},
+		{"Errors/all", "", true, []float64{1, 0, 0, 0, 0, 0, 0}},
+		{"Errors/allWeb", "", true, []float64{1, 0, 0, 0, 0, 0, 0}},
+		{"Errors/WebTransaction/Pattern" + helloPath, "", true, []float64{1, 0, 0, 0, 0, 0, 0}},
+	})
+}
+
+func TestInstrumentedHandlerExplicitName(t *testing.T) {
+	app := testApp(nil, nil, t)
+
+	handler := InstrumentedHandler(app, "myName", http.HandlerFunc(myErrorHandler))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, helloRequest)
+
+	app.h.ExpectMetrics(t, []internal.WantMetric{
+		{"WebTransaction/Pattern/myName", "", true, nil},
+		{"WebTransaction", "", true, nil},
+		{"HttpDispatcher", "", true, nil},
+		{"Apdex", "", true, nil},
+		{"Apdex/Pattern/myName", "", false, nil},
+		{"Errors/all", "", true, []float64{1, 0, 0, 0, 0, 0, 0}},
+		{"Errors/allWeb", "", true, []float64{1, 0, 0, 0, 0, 0, 0}},
+		{"Errors/WebTransaction/Pattern/myName", "", true, []float64{1, 0, 0, 0, 0, 0, 0}},
+	})
+}
+
+// TestMiddlewareNestedWriterWrapping places two nested middlewares, each
+// of which swaps in its own wrapping ResponseWriter, inside Middleware,
+// to confirm that the innermost handler can still reach the Transaction
+// started by Middleware via FromContext even though the writer it sees
+// is neither the original ResponseWriter nor a Transaction.
+func TestMiddlewareNestedWriterWrapping(t *testing.T) {
+	app := testApp(nil, nil, t)
+
+	wrapOnce := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(&wrapResponseWriter{w}, r)
+		})
+	}
+
+	var found Transaction
+	var ok bool
+	innermost := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		found, ok = FromContext(r.Context())
+		if _, assertable := w.(Transaction); assertable {
+			t.Error("ResponseWriter should no longer be a Transaction once wrapped twice")
+		}
+		w.Write(helloResponse)
+	})
+
+	handler := Middleware(app)(wrapOnce(wrapOnce(innermost)))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, helloRequest)
+
+	if !ok || nil == found {
+		t.Fatal("Transaction not found in context", ok, found)
+	}
+	if out := w.Body.String(); "hello" != out {
+		t.Error(out)
+	}
+}
+
+func TestMiddlewareNilApplication(t *testing.T) {
+	called := false
+	handler := Middleware(nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		if _, ok := FromContext(r.Context()); ok {
+			t.Error("no Transaction should be present when app is nil")
+		}
+		w.Write(helloResponse)
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, helloRequest)
+
+	if !called {
+		t.Fatal("handler was not called")
+	}
+	if out := w.Body.String(); "hello" != out {
+		t.Error(out)
+	}
+}
+
+func TestInstrumentedHandlerNilApplication(t *testing.T) {
+	called := false
+	handler := InstrumentedHandler(nil, "myName", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		w.Write(helloResponse)
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, helloRequest)
+
+	if !called {
+		t.Fatal("handler was not called")
+	}
+}
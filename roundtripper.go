@@ -0,0 +1,47 @@
+package newrelic
+
+import "net/http"
+
+type roundTripper struct {
+	original http.RoundTripper
+	txn      Transaction
+}
+
+// RoundTrip implements http.RoundTripper.
+func (rt roundTripper) RoundTrip(r *http.Request) (*http.Response, error) {
+	original := rt.original
+	if nil == original {
+		original = http.DefaultTransport
+	}
+
+	txn := rt.txn
+	if nil == txn {
+		txn, _ = FromContext(r.Context())
+	}
+	if nil == txn {
+		return original.RoundTrip(r)
+	}
+
+	segment := StartExternalSegment(txn, r)
+	response, err := original.RoundTrip(r)
+	segment.Response = response
+	segment.End()
+
+	return response, err
+}
+
+// NewRoundTripper creates a new instrumented http.RoundTripper, suitable
+// for use as the Transport of an http.Client, that records an External
+// segment for every outbound request it sees.
+//
+// Pass the Transaction that should own the external segments, or nil to
+// have each outbound request look up its Transaction from the request's
+// context (see FromContext) -- useful when a single http.Client is
+// shared across many transactions.  If original is nil,
+// http.DefaultTransport is used.
+func NewRoundTripper(txn Transaction, original http.RoundTripper) http.RoundTripper {
+	return roundTripper{
+		original: original,
+		txn:      txn,
+	}
+}
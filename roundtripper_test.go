@@ -0,0 +1,113 @@
+package newrelic
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.datanerd.us/p/will/newrelic/internal"
+)
+
+func TestRoundTripper(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(200)
+	}))
+	defer server.Close()
+
+	outboundReq, err := http.NewRequest("GET", server.URL, nil)
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name          string
+		req           *http.Request
+		expectWebOnly bool
+	}{
+		{name: "background", req: nil, expectWebOnly: false},
+		{name: "web", req: sampleRequest, expectWebOnly: true},
+	}
+
+	for _, tc := range testCases {
+		app := testApp(nil, nil, t)
+		txn := app.StartTransaction("myName", nil, tc.req)
+
+		client := &http.Client{Transport: NewRoundTripper(txn, nil)}
+		resp, err := client.Do(outboundReq)
+		if nil != err {
+			t.Fatal(tc.name, err)
+		}
+		resp.Body.Close()
+
+		txn.End()
+
+		host := outboundReq.URL.Host
+		allMetric := "External/allOther"
+		if tc.expectWebOnly {
+			allMetric = "External/allWeb"
+		}
+
+		app.h.ExpectMetrics(t, []internal.WantMetric{
+			{"External/all", "", true, []float64{1, 0, 0, 0, 0, 0, 0}},
+			{allMetric, "", true, []float64{1, 0, 0, 0, 0, 0, 0}},
+			{"External/" + host + "/http", "", false, []float64{1, 0, 0, 0, 0, 0, 0}},
+		})
+
+		txnName := "OtherTransaction/Pattern/myName"
+		zone := ""
+		if tc.expectWebOnly {
+			txnName = "WebTransaction/Pattern/myName"
+			zone = "S"
+		}
+		app.h.ExpectTxnEvents(t, []internal.WantTxnEvent{
+			{Name: txnName, Zone: zone, Attributes: map[string]interface{}{"http.statusCode": 200}},
+		})
+	}
+}
+
+func TestRoundTripperFromContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(200)
+	}))
+	defer server.Close()
+
+	app := testApp(nil, nil, t)
+	txn := app.StartTransaction("myName", nil, nil)
+	ctx := NewContext(helloRequest.Context(), txn)
+
+	outboundReq, err := http.NewRequest("GET", server.URL, nil)
+	if nil != err {
+		t.Fatal(err)
+	}
+	outboundReq = outboundReq.WithContext(ctx)
+
+	client := &http.Client{Transport: NewRoundTripper(nil, nil)}
+	resp, err := client.Do(outboundReq)
+	if nil != err {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	txn.End()
+
+	host := outboundReq.URL.Host
+	app.h.ExpectMetrics(t, []internal.WantMetric{
+		{"External/all", "", true, []float64{1, 0, 0, 0, 0, 0, 0}},
+		{"External/allOther", "", true, []float64{1, 0, 0, 0, 0, 0, 0}},
+		{"External/" + host + "/http", "", false, []float64{1, 0, 0, 0, 0, 0, 0}},
+	})
+}
+
+func TestRoundTripperNoTransaction(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(200)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: NewRoundTripper(nil, nil)}
+	resp, err := client.Get(server.URL)
+	if nil != err {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+}
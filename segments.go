@@ -0,0 +1,64 @@
+package newrelic
+
+import (
+	"net/http"
+	"time"
+)
+
+// ExternalSegment instruments an outbound call to another service.  Call
+// StartExternalSegment to begin instrumentation and End when the
+// response has been received.  End counts the call against its host and
+// records the response status code; the call's duration is not recorded.
+type ExternalSegment struct {
+	StartTime time.Time
+	Request   *http.Request
+	Response  *http.Response
+
+	txn Transaction
+}
+
+// StartExternalSegment starts the instrumentation of an external call.
+// Pass in the request being sent to the external service.  The Request
+// and Response fields may be set any time before End is called.
+func StartExternalSegment(txn Transaction, request *http.Request) *ExternalSegment {
+	return &ExternalSegment{
+		StartTime: time.Now(),
+		Request:   request,
+		txn:       txn,
+	}
+}
+
+// externalSegmentRecorder is implemented by the Transaction's concrete
+// type (internal.txn).  Recording external metrics requires internal
+// bookkeeping that isn't part of the public Transaction interface, so
+// segments reach it through this narrower interface instead.  The
+// method is exported because an interface's unexported methods can
+// only be satisfied by types declared in the same package as the
+// interface, and the concrete Transaction type lives in package
+// internal, not here.
+type externalSegmentRecorder interface {
+	RecordExternalSegment(start, stop time.Time, host string, statusCode int)
+}
+
+// End finishes the external segment, recording it against the host the
+// Request was sent to.
+func (s *ExternalSegment) End() error {
+	if nil == s || nil == s.txn {
+		return nil
+	}
+
+	host := ""
+	if nil != s.Request && nil != s.Request.URL {
+		host = s.Request.URL.Host
+	}
+
+	statusCode := 0
+	if nil != s.Response {
+		statusCode = s.Response.StatusCode
+	}
+
+	if r, ok := s.txn.(externalSegmentRecorder); ok {
+		r.RecordExternalSegment(s.StartTime, time.Now(), host, statusCode)
+	}
+	return nil
+}